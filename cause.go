@@ -5,22 +5,32 @@ import (
 )
 
 /*
-RootCause unwraps the original error that caused the current one.
+RootCause unwraps the original error that caused the current one, preserving
+its concrete type so that a type assertion or errors.As on the result still
+works.
 
 	_, err := f()
 	if perr, ok := stacktrace.RootCause(err).(*ParsingError); ok {
 		showError(perr.Line, perr.Column, perr.Text)
 	}
+
+If a *Stacktrace in the chain has no cause of its own, RootCause returns
+that *Stacktrace rather than fabricating a plain error from its message.
 */
 func RootCause(err error) error {
 	var st *Stacktrace
-	for {
-		if !errors.As(err, &st) {
-			return err
-		}
+	for errors.As(err, &st) {
 		if st.cause == nil {
-			return errors.New(st.message)
+			return st
 		}
 		err = st.cause
 	}
+	return err
+}
+
+// Unwrap returns the cause of st, letting errors.Is and errors.As walk past
+// a Stacktrace to inspect the error it wraps — including a sentinel error
+// like io.EOF, or a typed error further down the chain.
+func (st *Stacktrace) Unwrap() error {
+	return st.cause
 }
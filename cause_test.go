@@ -0,0 +1,68 @@
+package stacktrace
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+type parsingError struct {
+	Line int
+}
+
+func (e *parsingError) Error() string { return "parse error" }
+
+func TestErrorsAsReachesTypedCause(t *testing.T) {
+	err := Propagate(Wrap(&parsingError{Line: 7}), "failed to parse")
+
+	var target *parsingError
+	if !errors.As(err, &target) {
+		t.Fatal("expected errors.As to find the *parsingError cause")
+	}
+	if target.Line != 7 {
+		t.Errorf("target.Line = %d, want 7", target.Line)
+	}
+}
+
+func TestErrorsIsReachesSentinelCause(t *testing.T) {
+	err := Propagate(io.EOF, "reading failed")
+
+	if !errors.Is(err, io.EOF) {
+		t.Error("expected errors.Is to find io.EOF through the Stacktrace chain")
+	}
+	if errors.Is(err, io.ErrClosedPipe) {
+		t.Error("expected errors.Is to reject an unrelated sentinel")
+	}
+}
+
+func TestRootCausePreservesType(t *testing.T) {
+	pe := &parsingError{Line: 3}
+	err := Propagate(Wrap(pe), "failed to parse")
+
+	rc := RootCause(err)
+	if rc != error(pe) {
+		t.Errorf("RootCause = %#v, want the original *parsingError value", rc)
+	}
+}
+
+func TestRootCauseOnBareStacktrace(t *testing.T) {
+	err := NewMessageWithCode(NoCode, "no cause here")
+
+	rc := RootCause(err)
+	st, ok := rc.(*Stacktrace)
+	if !ok {
+		t.Fatalf("RootCause = %T, want *Stacktrace (not a fabricated errors.New)", rc)
+	}
+	if st.Error() != "no cause here" {
+		t.Errorf("RootCause.Error() = %q, want %q", st.Error(), "no cause here")
+	}
+}
+
+func TestUnwrapReturnsCause(t *testing.T) {
+	cause := errors.New("boom")
+	err := Wrap(cause).(*Stacktrace)
+
+	if err.Unwrap() != cause {
+		t.Errorf("Unwrap() = %v, want %v", err.Unwrap(), cause)
+	}
+}
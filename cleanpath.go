@@ -0,0 +1,41 @@
+package stacktrace
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// RemoveGoPath makes a path relative to one of the src directories in the
+// GOPATH environment variable, or to GOROOT/src for packages in the standard
+// library. If the path is not contained by any of those directories, it is
+// returned unchanged.
+func RemoveGoPath(path string) string {
+	for _, prefix := range goPaths() {
+		if rel, err := filepath.Rel(prefix, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return path
+}
+
+func goPaths() []string {
+	var paths []string
+	sep := string(filepath.Separator)
+
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		gopath = filepath.Join(os.Getenv("HOME"), "go")
+	}
+	for _, p := range filepath.SplitList(gopath) {
+		paths = append(paths, strings.TrimSuffix(p, sep)+sep+"src"+sep)
+	}
+
+	goroot := runtime.GOROOT()
+	if goroot != "" {
+		paths = append(paths, strings.TrimSuffix(goroot, sep)+sep+"src"+sep)
+	}
+
+	return paths
+}
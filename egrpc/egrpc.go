@@ -0,0 +1,64 @@
+// Package egrpc adapts stacktrace errors to gRPC status errors: it turns
+// the error code already tracked on a *stacktrace.Stacktrace into an actual
+// codes.Code on the wire instead of leaving it as an inert integer.
+package egrpc
+
+import (
+	"context"
+
+	stacktrace "github.com/iandri/etrace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+/*
+Debug controls whether the gRPC status message includes the full
+stacktrace, with its file paths and function names, instead of just the
+brief one-line messages of the error's cause chain. Leave this false in
+production deployments.
+*/
+var Debug = false
+
+/*
+UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that translates
+any error returned by the handler into a gRPC status error, mapping
+stacktrace.ErrorCode to codes.Code via the given table. A code with no entry
+in mapping, or an error with no code attached at all, becomes codes.Internal.
+
+	srv := grpc.NewServer(grpc.UnaryInterceptor(egrpc.UnaryServerInterceptor(map[stacktrace.ErrorCode]codes.Code{
+		EcodeManifestNotFound: codes.NotFound,
+		EcodeBadInput:         codes.InvalidArgument,
+	})))
+*/
+func UnaryServerInterceptor(mapping map[stacktrace.ErrorCode]codes.Code) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+
+		code, ok := mapping[stacktrace.GetCode(err)]
+		if !ok {
+			code = codes.Internal
+		}
+		return resp, status.Error(code, message(err))
+	}
+}
+
+// message returns the brief one-line rendering of err's cause chain
+// (stacktrace.FormatBrief) by default, without the file
+// paths and function names a *stacktrace.Stacktrace's Error() includes
+// (DefaultFormat is FFormatFull) — those are server-side implementation
+// detail that shouldn't unconditionally go out over the wire. Set Debug to
+// include them.
+func message(err error) string {
+	st, ok := err.(*stacktrace.Stacktrace)
+	if !ok {
+		return err.Error()
+	}
+	if Debug {
+		return stacktrace.FormatFull(st)
+	}
+	return stacktrace.FormatBrief(st)
+}
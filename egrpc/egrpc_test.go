@@ -0,0 +1,101 @@
+package egrpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	stacktrace "github.com/iandri/etrace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	ecodeNotFound stacktrace.ErrorCode = 1
+	ecodeBadInput stacktrace.ErrorCode = 2
+)
+
+func intercept(t *testing.T, handler grpc.UnaryHandler) error {
+	t.Helper()
+	interceptor := UnaryServerInterceptor(map[stacktrace.ErrorCode]codes.Code{
+		ecodeNotFound: codes.NotFound,
+		ecodeBadInput: codes.InvalidArgument,
+	})
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+	return err
+}
+
+func TestUnaryServerInterceptorMapsKnownCode(t *testing.T) {
+	err := intercept(t, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, stacktrace.NewErrorWithCode(ecodeNotFound, "widget missing")
+	})
+
+	st, ok := status.FromError(err)
+	if !ok {
+		t.Fatalf("expected a gRPC status error, got %v", err)
+	}
+	if st.Code() != codes.NotFound {
+		t.Errorf("Code() = %v, want %v", st.Code(), codes.NotFound)
+	}
+}
+
+func TestUnaryServerInterceptorDefaultsUnmappedCodeToInternal(t *testing.T) {
+	err := intercept(t, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, stacktrace.NewError("unclassified failure")
+	})
+
+	st, ok := status.FromError(err)
+	if !ok {
+		t.Fatalf("expected a gRPC status error, got %v", err)
+	}
+	if st.Code() != codes.Internal {
+		t.Errorf("Code() = %v, want %v", st.Code(), codes.Internal)
+	}
+}
+
+func TestUnaryServerInterceptorOmitsTraceByDefault(t *testing.T) {
+	Debug = false
+	err := intercept(t, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, stacktrace.Propagate(
+			stacktrace.NewErrorWithCode(ecodeBadInput, "widget missing"),
+			"loading widget",
+		)
+	})
+
+	st, _ := status.FromError(err)
+	if st.Message() != "loading widget: widget missing" {
+		t.Errorf("Message() = %q, want %q", st.Message(), "loading widget: widget missing")
+	}
+	if strings.Contains(st.Message(), ".go:") {
+		t.Errorf("Message() leaked file/line info: %q", st.Message())
+	}
+}
+
+func TestUnaryServerInterceptorIncludesTraceWithDebug(t *testing.T) {
+	Debug = true
+	defer func() { Debug = false }()
+
+	err := intercept(t, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, stacktrace.NewError("boom")
+	})
+
+	st, _ := status.FromError(err)
+	if !strings.Contains(st.Message(), ".go:") {
+		t.Errorf("expected Debug message to include file:line info, got %q", st.Message())
+	}
+}
+
+func TestUnaryServerInterceptorPassesThroughSuccess(t *testing.T) {
+	want := "ok"
+	resp, err := UnaryServerInterceptor(nil)(context.Background(), nil, &grpc.UnaryServerInfo{},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return want, nil
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp != want {
+		t.Errorf("resp = %v, want %v", resp, want)
+	}
+}
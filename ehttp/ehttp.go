@@ -0,0 +1,102 @@
+// Package ehttp adapts stacktrace errors to HTTP responses: it turns the
+// code and status code already tracked on a *stacktrace.Stacktrace into an
+// actual JSON response instead of leaving them as inert integers.
+package ehttp
+
+import (
+	"encoding/json"
+	"net/http"
+
+	stacktrace "github.com/iandri/etrace"
+)
+
+/*
+Debug controls whether WriteError includes a stacktrace in the response
+body. Leave this false in production deployments: the call sites and file
+paths in a trace are implementation detail, not part of the API contract.
+*/
+var Debug = false
+
+type errorBody struct {
+	Code    *int   `json:"code,omitempty"`
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+	Trace   string `json:"trace,omitempty"`
+}
+
+/*
+WriteError writes err to w as a JSON body of the form
+{"code", "status", "message", "trace"}. The HTTP status written comes from
+stacktrace.GetStatusCode(err), defaulting to http.StatusInternalServerError
+when err carries no status code of its own. For a *stacktrace.Stacktrace,
+"message" is stacktrace.FormatBrief(err): the messages of its cause chain on
+one line, without file paths or function names; for any other error it is
+err.Error(). "trace" is only included when Debug is set.
+*/
+func WriteError(w http.ResponseWriter, err error) {
+	status := stacktrace.GetStatusCode(err)
+	if status == stacktrace.NoStatusCode {
+		status = http.StatusInternalServerError
+	}
+
+	body := errorBody{
+		Status:  status,
+		Message: message(err),
+	}
+	if code := stacktrace.GetCode(err); code != stacktrace.NoCode {
+		c := int(code)
+		body.Code = &c
+	}
+	if Debug {
+		body.Trace = trace(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// message returns the brief one-line rendering of err's cause chain, without
+// the file paths and function names that err.Error() includes by default
+// (DefaultFormat is FFormatFull). Those belong in "trace", not in a field
+// every client sees.
+func message(err error) string {
+	if st, ok := err.(*stacktrace.Stacktrace); ok {
+		return stacktrace.FormatBrief(st)
+	}
+	return err.Error()
+}
+
+func trace(err error) string {
+	if st, ok := err.(*stacktrace.Stacktrace); ok {
+		return stacktrace.FormatFull(st)
+	}
+	return err.Error()
+}
+
+/*
+HandlerFunc is like http.HandlerFunc, but may return an error instead of
+writing a response body itself.
+*/
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+/*
+Handler adapts f into an http.Handler. If f returns a non-nil error, Handler
+writes it with WriteError; this saves every handler from having to remember
+to do so itself.
+
+	mux.Handle("/widgets/", ehttp.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		widget, err := loadWidget(r)
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to load widget")
+		}
+		return json.NewEncoder(w).Encode(widget)
+	}))
+*/
+func Handler(f HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := f(w, r); err != nil {
+			WriteError(w, err)
+		}
+	})
+}
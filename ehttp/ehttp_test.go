@@ -0,0 +1,102 @@
+package ehttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	stacktrace "github.com/iandri/etrace"
+)
+
+func TestWriteErrorUsesStatusCodeAndOmitsTrace(t *testing.T) {
+	Debug = false
+	err := stacktrace.PropagateWithStatusCode(
+		stacktrace.NewErrorWithCode(7, "widget missing"),
+		http.StatusBadRequest,
+		"loading widget",
+	)
+
+	rec := httptest.NewRecorder()
+	WriteError(rec, err)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	var body errorBody
+	if decodeErr := json.Unmarshal(rec.Body.Bytes(), &body); decodeErr != nil {
+		t.Fatalf("decoding response body: %v", decodeErr)
+	}
+	if body.Code == nil || *body.Code != 7 {
+		t.Errorf("Code = %v, want 7", body.Code)
+	}
+	if body.Message != "loading widget: widget missing" {
+		t.Errorf("Message = %q, want %q", body.Message, "loading widget: widget missing")
+	}
+	if body.Trace != "" {
+		t.Errorf("expected no trace without Debug, got %q", body.Trace)
+	}
+	if strings.Contains(body.Message, ".go:") {
+		t.Errorf("Message leaked file/line info: %q", body.Message)
+	}
+}
+
+func TestWriteErrorWithDebugIncludesTrace(t *testing.T) {
+	Debug = true
+	defer func() { Debug = false }()
+
+	err := stacktrace.NewError("boom")
+	rec := httptest.NewRecorder()
+	WriteError(rec, err)
+
+	var body errorBody
+	if decodeErr := json.Unmarshal(rec.Body.Bytes(), &body); decodeErr != nil {
+		t.Fatalf("decoding response body: %v", decodeErr)
+	}
+	if !strings.Contains(body.Trace, ".go:") {
+		t.Errorf("expected Trace to include file:line info, got %q", body.Trace)
+	}
+}
+
+func TestWriteErrorDefaultsInternalServerError(t *testing.T) {
+	Debug = false
+	rec := httptest.NewRecorder()
+	WriteError(rec, stacktrace.NewError("unclassified failure"))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestHandlerWritesReturnedError(t *testing.T) {
+	Debug = false
+	h := Handler(func(w http.ResponseWriter, r *http.Request) error {
+		return stacktrace.NewErrorWithStatusCode(http.StatusTeapot, "no tea")
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusTeapot)
+	}
+}
+
+func TestHandlerNoErrorWritesNothingSpecial(t *testing.T) {
+	h := Handler(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("expected empty body, got %q", rec.Body.String())
+	}
+}
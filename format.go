@@ -24,37 +24,33 @@ DefaultFormat defines the behavior of err.Error() when called on a stacktrace,
 as well as the default behavior of the "%v", "%s" and "%q" formatting
 specifiers. By default, all of these produce a full stacktrace including line
 number information. To have them produce a condensed single-line output, set
-this value to stacktrace.FormatBrief.
+this value to stacktrace.FFormatBrief, or to the name of any format
+registered with RegisterFormat.
 
 The formatting specifier "%+s" can be used to force a full stacktrace regardless
 of the value of DefaultFormat. Similarly, the formatting specifier "%#s" can be
-used to force a brief output.
+used to force a brief output, and "%j" can be used to force structured JSON
+output regardless of DefaultFormat.
 */
 var DefaultFormat = FFormatFull
 
-// Format is the type of the two possible values of stacktrace.DefaultFormat.
-type Format int
-
-const (
-	// FormatFull means format as a full stacktrace including line number information.
-	FFormatFull Format = iota
-	// FormatBrief means Format on a single line without line number information.
-	FFormatBrief
-)
-
 var _ fmt.Formatter = (*Stacktrace)(nil)
 
 func (st *Stacktrace) Format(f fmt.State, c rune) {
+	if c == 'j' { // "%j"
+		fmt.Fprint(f, FormatJSON(st))
+		return
+	}
+
 	var text string
 	if f.Flag('+') && !f.Flag('#') && c == 's' { // "%+s"
 		text = FormatFull(st)
 	} else if f.Flag('#') && !f.Flag('+') && c == 's' { // "%#s"
 		text = FormatBrief(st)
+	} else if formatter, ok := formatterFor(DefaultFormat); ok {
+		text = formatter.Format(st)
 	} else {
-		text = map[Format]func(*Stacktrace) string{
-			FFormatFull:  FormatFull,
-			FFormatBrief: FormatBrief,
-		}[DefaultFormat](st)
+		text = FormatFull(st)
 	}
 
 	formatString := "%"
@@ -86,12 +82,21 @@ func FormatFull(st *Stacktrace) string {
 	for curr, ok := st, true; ok; curr, ok = curr.cause.(*Stacktrace) {
 		str += curr.message
 
-		if curr.file != "" {
-			newline()
-			if curr.function == "" {
-				str += fmt.Sprintf(" %v:%v", curr.file, curr.line)
+		for i, fr := range curr.Frames() {
+			if i == 0 {
+				newline()
+				if fr.Function == "" {
+					str += fmt.Sprintf(" %v:%v", fr.File, fr.Line)
+				} else {
+					str += fmt.Sprintf(" %v:%v (%v)", fr.File, fr.Line, fr.Function)
+				}
+				continue
+			}
+			str += "\n\t"
+			if fr.Function == "" {
+				str += fmt.Sprintf("%v:%v", fr.File, fr.Line)
 			} else {
-				str += fmt.Sprintf(" %v:%v (%v)", curr.file, curr.line, curr.function)
+				str += fmt.Sprintf("%v:%v (%v)", fr.File, fr.Line, fr.Function)
 			}
 		}
 
@@ -0,0 +1,124 @@
+package stacktrace
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Format names a registered Formatter, for use as stacktrace.DefaultFormat
+// or when calling RegisterFormat.
+type Format string
+
+const (
+	// FFormatFull renders a full stacktrace including line number information.
+	FFormatFull Format = "full"
+	// FFormatBrief renders on a single line without line number information.
+	FFormatBrief Format = "brief"
+	// FFormatJSON renders the structured JSON produced by FormatJSON.
+	FFormatJSON Format = "json"
+	// FFormatCausedBy renders in the Java-style "Caused by:" multi-line format.
+	FFormatCausedBy Format = "caused-by"
+)
+
+/*
+Formatter renders a Stacktrace, and the cause chain beneath it, into a
+string. FormatFull, FormatBrief, FormatJSON and FormatCausedBy are the
+Formatters registered by default; plug in your own (logfmt, a
+Sentry-compatible shape, ...) with RegisterFormat.
+*/
+type Formatter interface {
+	Format(st *Stacktrace) string
+}
+
+// FormatterFunc adapts an ordinary func(*Stacktrace) string to a Formatter.
+type FormatterFunc func(st *Stacktrace) string
+
+// Format calls f(st).
+func (f FormatterFunc) Format(st *Stacktrace) string {
+	return f(st)
+}
+
+var (
+	formattersMu sync.RWMutex
+	formatters   = map[Format]Formatter{
+		FFormatFull:     FormatterFunc(FormatFull),
+		FFormatBrief:    FormatterFunc(FormatBrief),
+		FFormatJSON:     FormatterFunc(FormatJSON),
+		FFormatCausedBy: FormatterFunc(FormatCausedBy),
+	}
+)
+
+/*
+RegisterFormat makes f selectable under name, either by setting
+DefaultFormat to name or by formatting with "%v" after doing so. Registering
+under one of the built-in names (FFormatFull, FFormatBrief, FFormatJSON,
+FFormatCausedBy) replaces that built-in formatter.
+
+RegisterFormat is safe to call concurrently with formatting an error.
+*/
+func RegisterFormat(name Format, f Formatter) {
+	formattersMu.Lock()
+	defer formattersMu.Unlock()
+	formatters[name] = f
+}
+
+// formatterFor looks up the Formatter registered under name, if any. It is
+// safe to call concurrently with RegisterFormat.
+func formatterFor(name Format) (Formatter, bool) {
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+	f, ok := formatters[name]
+	return f, ok
+}
+
+/*
+FormatCausedBy renders st and its cause chain in the Java-style
+"Caused by:" format familiar from exception stacktraces: the outermost
+message and its frames, then each wrapped cause introduced by its own
+"Caused by:" line.
+*/
+func FormatCausedBy(st *Stacktrace) string {
+	var str string
+
+	for curr, ok := st, true; ok; curr, ok = curr.cause.(*Stacktrace) {
+		if curr.message == "" && curr.cause != nil {
+			// An empty message means this frame exists only to wrap its
+			// cause (the common Propagate(NewError(...), ...) shape, since
+			// NewError stores its text on the wrapped cause, not here).
+			// Fold straight into the cause's text instead of emitting an
+			// empty "Caused by:" block and a duplicate frame dump for it.
+			if _, isStacktrace := curr.cause.(*Stacktrace); !isStacktrace {
+				if str != "" {
+					str += "Caused by: "
+				}
+				str += curr.cause.Error()
+				break
+			}
+			continue
+		}
+
+		if str != "" {
+			str += "Caused by: "
+		}
+		str += curr.message
+
+		for _, fr := range curr.Frames() {
+			str += "\n\tat "
+			if fr.Function == "" {
+				str += fmt.Sprintf("%v:%v", fr.File, fr.Line)
+			} else {
+				str += fmt.Sprintf("%v(%v:%v)", fr.Function, fr.File, fr.Line)
+			}
+		}
+
+		if curr.cause != nil {
+			str += "\n"
+			if _, ok := curr.cause.(*Stacktrace); !ok {
+				str += "Caused by: "
+				str += curr.cause.Error()
+			}
+		}
+	}
+
+	return str
+}
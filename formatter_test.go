@@ -0,0 +1,96 @@
+package stacktrace
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRegisterFormatIsSelectableViaDefaultFormat(t *testing.T) {
+	original := DefaultFormat
+	defer func() { DefaultFormat = original }()
+
+	RegisterFormat("shout", FormatterFunc(func(st *Stacktrace) string {
+		return strings.ToUpper(FormatBrief(st))
+	}))
+	DefaultFormat = "shout"
+
+	err := NewError("quiet failure")
+	if got := fmt.Sprintf("%v", err); got != "QUIET FAILURE" {
+		t.Errorf("%%v = %q, want %q", got, "QUIET FAILURE")
+	}
+}
+
+func TestRegisterFormatCanReplaceBuiltin(t *testing.T) {
+	original := DefaultFormat
+	defer func() {
+		DefaultFormat = original
+		RegisterFormat(FFormatBrief, FormatterFunc(FormatBrief))
+	}()
+
+	RegisterFormat(FFormatBrief, FormatterFunc(func(st *Stacktrace) string {
+		return "overridden"
+	}))
+	DefaultFormat = FFormatBrief
+
+	if got := fmt.Sprintf("%v", NewError("boom")); got != "overridden" {
+		t.Errorf("%%v = %q, want %q", got, "overridden")
+	}
+}
+
+func TestFormatCausedByRendersEachCause(t *testing.T) {
+	err := Propagate(Propagate(NewError("leaf failed"), "middle failed"), "outer failed")
+	st := err.(*Stacktrace)
+
+	out := FormatCausedBy(st)
+	for _, want := range []string{"outer failed", "Caused by: middle failed", "Caused by: leaf failed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("FormatCausedBy output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestFormatCausedBySkipsEmptyMessageWrapper(t *testing.T) {
+	// NewError stores its text on the wrapped cause, leaving the outer
+	// Stacktrace's own message empty; FormatCausedBy must not emit a blank
+	// "Caused by:" block (with a duplicate frame dump) for that wrapper.
+	st := Propagate(NewError("leaf failed"), "outer failed").(*Stacktrace)
+
+	out := FormatCausedBy(st)
+	if strings.Contains(out, "Caused by: \n") || strings.Contains(out, "Caused by: \t") {
+		t.Errorf("FormatCausedBy emitted an empty Caused-by block:\n%s", out)
+	}
+	if n := strings.Count(out, "Caused by:"); n != 1 {
+		t.Errorf("expected exactly one \"Caused by:\" line, got %d:\n%s", n, out)
+	}
+	if !strings.Contains(out, "Caused by: leaf failed") {
+		t.Errorf("FormatCausedBy output missing %q:\n%s", "Caused by: leaf failed", out)
+	}
+}
+
+func TestFormatterForReportsNoMatchWhenUnregistered(t *testing.T) {
+	if _, ok := formatterFor("does-not-exist"); ok {
+		t.Error("expected formatterFor to report no match for an unregistered name")
+	}
+}
+
+// TestRegisterFormatConcurrentWithFormatting exercises RegisterFormat and
+// formatting concurrently; it is meaningful primarily under "go test -race".
+func TestRegisterFormatConcurrentWithFormatting(t *testing.T) {
+	err := NewError("concurrent boom")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func(n int) {
+			defer wg.Done()
+			RegisterFormat(Format(fmt.Sprintf("custom-%d", n)), FormatterFunc(FormatBrief))
+		}(i)
+		go func() {
+			defer wg.Done()
+			_ = fmt.Sprintf("%v", err)
+		}()
+	}
+	wg.Wait()
+}
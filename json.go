@@ -0,0 +1,144 @@
+package stacktrace
+
+import "encoding/json"
+
+/*
+jsonFrame is the wire representation of a single Stacktrace in a cause
+chain. Each cause is nested under the previous frame's "cause" key, so the
+whole chain round-trips through json.Marshal/json.Unmarshal as one tree.
+*/
+type jsonFrame struct {
+	Message    string     `json:"message"`
+	File       string     `json:"file,omitempty"`
+	Line       int        `json:"line,omitempty"`
+	Function   string     `json:"function,omitempty"`
+	Code       *ErrorCode `json:"code,omitempty"`
+	StatusCode *int       `json:"status_code,omitempty"`
+	Kind       string     `json:"kind,omitempty"`
+	// Plain marks a frame that came from a non-Stacktrace cause, so
+	// toStacktrace knows to rebuild it as a plain error instead of a
+	// *Stacktrace, regardless of which other fields happen to be empty.
+	Plain bool       `json:"plain,omitempty"`
+	Cause *jsonFrame `json:"cause,omitempty"`
+}
+
+func newJSONFrame(st *Stacktrace) *jsonFrame {
+	jf := &jsonFrame{
+		Message:  st.message,
+		File:     st.file,
+		Line:     st.line,
+		Function: st.function,
+	}
+	if st.code != NoCode {
+		code := st.code
+		jf.Code = &code
+	}
+	if st.statusCode != NoStatusCode {
+		statusCode := st.statusCode
+		jf.StatusCode = &statusCode
+	}
+	if st.kind != KindUnknown {
+		jf.Kind = st.kind.String()
+	}
+
+	switch cause := st.cause.(type) {
+	case nil:
+	case *Stacktrace:
+		jf.Cause = newJSONFrame(cause)
+	default:
+		jf.Cause = &jsonFrame{Message: cause.Error(), Plain: true}
+	}
+
+	return jf
+}
+
+func (jf *jsonFrame) toStacktrace() *Stacktrace {
+	if jf == nil {
+		return nil
+	}
+
+	st := &Stacktrace{
+		message:    jf.Message,
+		file:       jf.File,
+		line:       jf.Line,
+		function:   jf.Function,
+		code:       NoCode,
+		statusCode: NoStatusCode,
+		kind:       KindUnknown,
+	}
+	if jf.Code != nil {
+		st.code = *jf.Code
+	}
+	if jf.StatusCode != nil {
+		st.statusCode = *jf.StatusCode
+	}
+	if jf.Kind != "" {
+		st.kind = kindFromString(jf.Kind)
+	}
+	if jf.Cause != nil {
+		if jf.Cause.Plain {
+			// Marked by MarshalJSON as a plain, non-Stacktrace cause;
+			// preserve it as such rather than inventing a Stacktrace for it.
+			st.cause = errorString(jf.Cause.Message)
+		} else {
+			st.cause = jf.Cause.toStacktrace()
+		}
+	}
+
+	return st
+}
+
+func kindFromString(s string) Kind {
+	for k := KindUnknown; k <= KindInternal; k++ {
+		if k.String() == s {
+			return k
+		}
+	}
+	return KindUnknown
+}
+
+// errorString is a minimal error, analogous to errors.errorString, used to
+// represent a non-Stacktrace cause recovered from JSON.
+type errorString string
+
+func (e errorString) Error() string { return string(e) }
+
+// MarshalJSON renders st and its cause chain as structured JSON, with each
+// frame holding its message, source location, code/status code/kind, and a
+// nested "cause". Each frame records only the immediate caller's file, line
+// and function, not every frame FormatFull renders from the captured stack.
+func (st *Stacktrace) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONFrame(st))
+}
+
+// UnmarshalJSON populates st from the structured JSON produced by
+// MarshalJSON, rebuilding the cause chain. A cause frame marked "plain" (a
+// non-Stacktrace cause) is rebuilt as a plain error rather than a
+// Stacktrace.
+func (st *Stacktrace) UnmarshalJSON(data []byte) error {
+	var jf jsonFrame
+	if err := json.Unmarshal(data, &jf); err != nil {
+		return err
+	}
+	*st = *jf.toStacktrace()
+	return nil
+}
+
+// FormatJSON renders st as the structured JSON object documented on
+// MarshalJSON. Unlike FormatFull and FormatBrief, it is meant to be
+// consumed by machines (log shippers, tracing backends) rather than read
+// by a human staring at a terminal.
+func FormatJSON(st *Stacktrace) string {
+	b, err := json.Marshal(st)
+	if err != nil {
+		// json.Marshal on a *Stacktrace only fails if Marshal itself is
+		// broken, but don't let a formatter panic or swallow the error.
+		return `{"message":` + jsonString(st.Error()) + `}`
+	}
+	return string(b)
+}
+
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,120 @@
+package stacktrace
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMarshalJSONFields(t *testing.T) {
+	cause := NewErrorWithKind(KindNotFound, "widget missing")
+	err := PropagateWithCode(cause, 7, "loading widget")
+
+	b, marshalErr := json.Marshal(err)
+	if marshalErr != nil {
+		t.Fatalf("MarshalJSON: %v", marshalErr)
+	}
+
+	var top jsonFrame
+	if err := json.Unmarshal(b, &top); err != nil {
+		t.Fatalf("Unmarshal into jsonFrame: %v", err)
+	}
+
+	if top.Message != "loading widget" {
+		t.Errorf("Message = %q, want %q", top.Message, "loading widget")
+	}
+	if top.Code == nil || *top.Code != 7 {
+		t.Errorf("Code = %v, want 7", top.Code)
+	}
+	if top.File == "" {
+		t.Error("expected File to be set")
+	}
+	if top.Cause == nil {
+		t.Fatal("expected a nested cause")
+	}
+	if top.Cause.Kind != "not found" {
+		t.Errorf("Cause.Kind = %q, want %q", top.Cause.Kind, "not found")
+	}
+	// NewErrorWithKind wraps its fmt.Errorf-built message as the cause's own
+	// cause, the same way NewError does, so the text shows up one level deeper.
+	if top.Cause.Cause == nil || top.Cause.Cause.Message != "widget missing" {
+		t.Errorf("Cause.Cause.Message = %+v, want %q", top.Cause.Cause, "widget missing")
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	cause := NewErrorWithKind(KindNotFound, "widget missing")
+	original := PropagateWithCode(cause, 7, "loading widget")
+
+	b, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var round Stacktrace
+	if err := json.Unmarshal(b, &round); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	// FormatBrief never touches file/line/function, so it round-trips
+	// exactly even though the full multi-frame stack does not.
+	originalSt, ok := original.(*Stacktrace)
+	if !ok {
+		t.Fatalf("original is %T, want *Stacktrace", original)
+	}
+	if FormatBrief(&round) != FormatBrief(originalSt) {
+		t.Errorf("round-tripped FormatBrief = %q, want %q", FormatBrief(&round), FormatBrief(originalSt))
+	}
+	if GetCode(&round) != 7 {
+		t.Errorf("round-tripped GetCode = %v, want 7", GetCode(&round))
+	}
+	if !IsNotFound(&round) {
+		t.Error("expected round-tripped error to still be IsNotFound")
+	}
+}
+
+func TestJSONRoundTripPlainCause(t *testing.T) {
+	original := NewError("leaf failed")
+
+	b, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var round Stacktrace
+	if err := json.Unmarshal(b, &round); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	originalSt, ok := original.(*Stacktrace)
+	if !ok {
+		t.Fatalf("original is %T, want *Stacktrace", original)
+	}
+	if FormatBrief(&round) != FormatBrief(originalSt) {
+		t.Errorf("round-tripped FormatBrief = %q, want %q", FormatBrief(&round), FormatBrief(originalSt))
+	}
+	if round.file == "" {
+		t.Error("expected round-tripped file to survive even without a captured pcs slice")
+	}
+}
+
+func TestJSONRoundTripPreservesBareStacktraceCause(t *testing.T) {
+	// NewMessageWithCode with NoCode produces a *Stacktrace with no
+	// file/line/function/code/kind of its own — indistinguishable by field
+	// emptiness from a plain, non-Stacktrace cause. The "plain" discriminator
+	// on jsonFrame, not field-emptiness guessing, must be what decides this.
+	original := Propagate(NewMessageWithCode(NoCode, "bare cause"), "outer")
+
+	b, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var round Stacktrace
+	if err := json.Unmarshal(b, &round); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if _, ok := round.cause.(*Stacktrace); !ok {
+		t.Fatalf("round.cause is %T, want *Stacktrace", round.cause)
+	}
+}
@@ -0,0 +1,171 @@
+package stacktrace
+
+import (
+	"errors"
+	"fmt"
+)
+
+/*
+Kind classifies the behavior of an error independently of any numeric
+ErrorCode. Where ErrorCode is an open, application-defined set of values,
+Kind is a small, fixed vocabulary describing how a caller should react to
+an error: should it retry, is the request bad, was the resource missing?
+
+	if stacktrace.IsNotFound(err) {
+		return nil, nil
+	}
+	return nil, stacktrace.Propagate(err, "Failed to load %v", id)
+
+An ordinary stacktrace.Propagate call preserves the kind of an error, the
+same way it preserves the error code.
+*/
+type Kind uint8
+
+const (
+	// KindUnknown is the kind of errors with no kind explicitly attached.
+	KindUnknown Kind = iota
+	// KindNotFound means the requested resource does not exist.
+	KindNotFound
+	// KindTimeout means the operation did not complete in time.
+	KindTimeout
+	// KindUnauthorized means the caller is not allowed to perform the operation.
+	KindUnauthorized
+	// KindConflict means the operation conflicts with the current state of the resource.
+	KindConflict
+	// KindTemporary means the operation failed but may succeed if retried.
+	KindTemporary
+	// KindPermanent means the operation failed and retrying will not help.
+	KindPermanent
+	// KindBadInput means the caller supplied invalid arguments.
+	KindBadInput
+	// KindAlreadyExists means the resource the caller tried to create already exists.
+	KindAlreadyExists
+	// KindCanceled means the operation was canceled by its caller.
+	KindCanceled
+	// KindInternal means the failure is an implementation detail, not something the caller caused.
+	KindInternal
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNotFound:
+		return "not found"
+	case KindTimeout:
+		return "timeout"
+	case KindUnauthorized:
+		return "unauthorized"
+	case KindConflict:
+		return "conflict"
+	case KindTemporary:
+		return "temporary"
+	case KindPermanent:
+		return "permanent"
+	case KindBadInput:
+		return "bad input"
+	case KindAlreadyExists:
+		return "already exists"
+	case KindCanceled:
+		return "canceled"
+	case KindInternal:
+		return "internal"
+	default:
+		return "unknown"
+	}
+}
+
+// Error lets a Kind be used as a target of errors.Is, e.g.
+// errors.Is(err, stacktrace.KindNotFound).
+func (k Kind) Error() string {
+	return k.String()
+}
+
+/*
+NewErrorWithKind is similar to NewError but also attaches a behavioral Kind.
+*/
+func NewErrorWithKind(kind Kind, msg string, vals ...interface{}) error {
+	e := fmt.Errorf(msg, vals...)
+	return create(e, NoCode, NoStatusCode, kind, "")
+}
+
+/*
+WrapWithKind is similar to Wrap but also attaches a behavioral Kind.
+*/
+func WrapWithKind(kind Kind, cause error) error {
+	if cause == nil {
+		// Allow calling WrapWithKind without checking whether there is error
+		return nil
+	}
+	return create(cause, NoCode, NoStatusCode, kind, "")
+}
+
+/*
+PropagateWithKind is similar to Propagate but also attaches a behavioral Kind.
+*/
+func PropagateWithKind(cause error, kind Kind, msg string, vals ...interface{}) error {
+	if cause == nil {
+		// Allow calling PropagateWithKind without checking whether there is error
+		return nil
+	}
+	return create(cause, NoCode, NoStatusCode, kind, msg, vals...)
+}
+
+/*
+GetKind extracts the behavioral Kind from a stacktrace error, including
+wrapped ones. GetKind returns the special value stacktrace.KindUnknown if
+err is nil or if there is no kind attached to err.
+*/
+func GetKind(err error) Kind {
+	var st *Stacktrace
+	if errors.As(err, &st) {
+		return st.kind
+	}
+
+	return KindUnknown
+}
+
+/*
+Is reports whether err, or one of the errors it wraps, has the given Kind.
+*/
+func Is(err error, k Kind) bool {
+	return errors.Is(err, k)
+}
+
+// Is implements the interface used by errors.Is, matching target against
+// the Kind attached to st.
+func (st *Stacktrace) Is(target error) bool {
+	k, ok := target.(Kind)
+	if !ok {
+		return false
+	}
+	return k != KindUnknown && st.kind == k
+}
+
+// IsNotFound reports whether err is of KindNotFound.
+func IsNotFound(err error) bool { return Is(err, KindNotFound) }
+
+// IsTimeout reports whether err is of KindTimeout.
+func IsTimeout(err error) bool { return Is(err, KindTimeout) }
+
+// IsUnauthorized reports whether err is of KindUnauthorized.
+func IsUnauthorized(err error) bool { return Is(err, KindUnauthorized) }
+
+// IsConflict reports whether err is of KindConflict.
+func IsConflict(err error) bool { return Is(err, KindConflict) }
+
+// IsTemporary reports whether err is of KindTemporary.
+func IsTemporary(err error) bool { return Is(err, KindTemporary) }
+
+// IsPermanent reports whether err is of KindPermanent.
+func IsPermanent(err error) bool { return Is(err, KindPermanent) }
+
+// IsBadInput reports whether err is of KindBadInput.
+func IsBadInput(err error) bool { return Is(err, KindBadInput) }
+
+// IsAlreadyExists reports whether err is of KindAlreadyExists.
+func IsAlreadyExists(err error) bool { return Is(err, KindAlreadyExists) }
+
+// IsCanceled reports whether err is of KindCanceled.
+func IsCanceled(err error) bool { return Is(err, KindCanceled) }
+
+// IsInternal reports whether err is of KindInternal.
+func IsInternal(err error) bool { return Is(err, KindInternal) }
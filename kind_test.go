@@ -0,0 +1,80 @@
+package stacktrace
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestKindPredicates(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want func(error) bool
+	}{
+		{"not found", NewErrorWithKind(KindNotFound, "missing"), IsNotFound},
+		{"timeout", NewErrorWithKind(KindTimeout, "too slow"), IsTimeout},
+		{"unauthorized", NewErrorWithKind(KindUnauthorized, "nope"), IsUnauthorized},
+		{"conflict", NewErrorWithKind(KindConflict, "clash"), IsConflict},
+		{"temporary", NewErrorWithKind(KindTemporary, "retry me"), IsTemporary},
+		{"permanent", NewErrorWithKind(KindPermanent, "give up"), IsPermanent},
+		{"bad input", NewErrorWithKind(KindBadInput, "bad"), IsBadInput},
+		{"already exists", NewErrorWithKind(KindAlreadyExists, "dup"), IsAlreadyExists},
+		{"canceled", NewErrorWithKind(KindCanceled, "stopped"), IsCanceled},
+		{"internal", NewErrorWithKind(KindInternal, "oops"), IsInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.want(tt.err) {
+				t.Errorf("expected predicate to match %v", tt.err)
+			}
+		})
+	}
+}
+
+func TestKindInheritedThroughPropagate(t *testing.T) {
+	cause := NewErrorWithKind(KindNotFound, "widget missing")
+	wrapped := Propagate(cause, "loading widget")
+
+	if !IsNotFound(wrapped) {
+		t.Errorf("expected kind to be inherited by Propagate, got GetKind=%v", GetKind(wrapped))
+	}
+}
+
+func TestKindOverriddenByOuterWrap(t *testing.T) {
+	cause := NewErrorWithKind(KindNotFound, "widget missing")
+	wrapped := PropagateWithKind(cause, KindInternal, "loading widget")
+
+	// GetKind inspects only the outermost Stacktrace, so an explicit kind
+	// at that level wins over whatever the cause carried.
+	if got := GetKind(wrapped); got != KindInternal {
+		t.Errorf("expected GetKind to report the outer override KindInternal, got %v", got)
+	}
+	// The predicate helpers use errors.Is, which walks the whole cause
+	// chain, so the cause's own kind is still visible through them.
+	if !IsNotFound(wrapped) {
+		t.Error("expected IsNotFound to still find the cause's kind in the chain")
+	}
+	if !IsInternal(wrapped) {
+		t.Errorf("expected KindInternal, got %v", GetKind(wrapped))
+	}
+}
+
+func TestGetKindNoKind(t *testing.T) {
+	if k := GetKind(NewError("plain")); k != KindUnknown {
+		t.Errorf("expected KindUnknown for an error with no kind, got %v", k)
+	}
+	if k := GetKind(nil); k != KindUnknown {
+		t.Errorf("expected KindUnknown for a nil error, got %v", k)
+	}
+}
+
+func TestIsWorksWithStdlibErrorsIs(t *testing.T) {
+	err := WrapWithKind(KindConflict, errors.New("underlying"))
+	if !errors.Is(err, KindConflict) {
+		t.Error("expected errors.Is(err, KindConflict) to succeed via (*Stacktrace).Is")
+	}
+	if errors.Is(err, KindTimeout) {
+		t.Error("expected errors.Is(err, KindTimeout) to fail for a KindConflict error")
+	}
+}
@@ -0,0 +1,367 @@
+package stacktrace
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"strings"
+)
+
+/*
+CleanPath function is applied to file paths before adding them to a stacktrace.
+By default, it makes the path relative to the $GOPATH environment variable.
+
+To remove some additional prefix like "github.com" from file paths in
+stacktraces, use something like:
+
+	stacktrace.CleanPath = func(path string) string {
+		path = stacktrace.RemoveGoPath(path)
+		path = strings.TrimPrefix(path, "github.com/")
+		return path
+	}
+*/
+var CleanPath = RemoveGoPath
+
+/*
+NewError is a drop-in replacement for fmt.Errorf that includes line number
+information. The canonical call looks like this:
+
+	if !IsOkay(arg) {
+		return stacktrace.NewError("Expected %v to be okay", arg)
+	}
+*/
+func NewError(msg string, vals ...interface{}) error {
+	e := fmt.Errorf(msg, vals...)
+	return create(e, NoCode, NoStatusCode, KindUnknown, "")
+}
+
+func Wrap(cause error) error {
+	if cause == nil {
+		// Allow calling Wrap without checking whether there is error
+		return nil
+	}
+	return create(cause, NoCode, NoStatusCode, KindUnknown, "")
+}
+
+func WrapWithCode(code ErrorCode, cause error) error {
+	if cause == nil {
+		// Allow calling WrapWithCode without checking whether there is error
+		return nil
+	}
+	return create(cause, code, NoStatusCode, KindUnknown, "")
+}
+
+func WrapWithStatusCode(code int, cause error) error {
+	if cause == nil {
+		// Allow calling WrapWithStatusCode without checking whether there is error
+		return nil
+	}
+	return create(cause, NoCode, code, KindUnknown, "")
+}
+
+/*
+Propagate wraps an error to include line number information. The msg and vals
+arguments work like the ones for fmt.Errorf.
+
+The message passed to Propagate should describe the action that failed,
+resulting in the cause. The canonical call looks like this:
+
+	result, err := process(arg)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Failed to process %v", arg)
+	}
+
+To write the message, ask yourself "what does this call do?" What does
+process(arg) do? It processes ${arg}, so the message is that we failed to
+process ${arg}.
+
+Pay attention that the message is not redundant with the one in err. If it is
+not possible to add any useful contextual information beyond what is already
+included in an error, msg can be an empty string:
+
+	func Something() error {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		err := reallySomething()
+		return stacktrace.Propagate(err, "")
+	}
+
+If cause is nil, Propagate returns nil. This allows elision of some "if err !=
+nil" checks.
+*/
+func Propagate(cause error, msg string, vals ...interface{}) error {
+	if cause == nil {
+		// Allow calling Propagate without checking whether there is error
+		return nil
+	}
+	return create(cause, NoCode, NoStatusCode, KindUnknown, msg, vals...)
+}
+
+func PropagateWithCode(cause error, code ErrorCode, msg string, vals ...interface{}) error {
+	if cause == nil {
+		// Allow calling PropagateWithCode without checking whether there is error
+		return nil
+	}
+	return create(cause, code, NoStatusCode, KindUnknown, msg, vals...)
+}
+
+func PropagateWithStatusCode(cause error, code int, msg string, vals ...interface{}) error {
+	if cause == nil {
+		// Allow calling PropagateWithStatusCode without checking whether there is error
+		return nil
+	}
+	return create(cause, NoCode, code, KindUnknown, msg, vals...)
+}
+
+/*
+ErrorCode is a code that can be attached to an error as it is passed/propagated
+up the stack.
+
+There is no predefined set of error codes. You define the ones relevant to your
+application:
+
+	const (
+		EcodeManifestNotFound = stacktrace.ErrorCode(iota)
+		EcodeBadInput
+		EcodeTimeout
+	)
+
+The one predefined error code is NoCode, which has a value of math.MaxUint16.
+Avoid using that value as an error code.
+
+An ordinary stacktrace.Propagate call preserves the error code of an error.
+*/
+type ErrorCode uint16
+
+/*
+NoCode is the error code of errors with no code explicitly attached.
+*/
+const NoCode ErrorCode = math.MaxUint16
+
+/*
+NoStatusCode is the status code of errors with no status code explicitly
+attached.
+*/
+const NoStatusCode int = -1
+
+/*
+NewErrorWithCode is similar to NewError but also attaches an error code.
+*/
+func NewErrorWithCode(code ErrorCode, msg string, vals ...interface{}) error {
+	e := fmt.Errorf(msg, vals...)
+	return create(e, code, NoStatusCode, KindUnknown, "")
+}
+
+func NewErrorWithStatusCode(statusCode int, msg string, vals ...interface{}) error {
+	e := fmt.Errorf(msg, vals...)
+	return create(e, NoCode, statusCode, KindUnknown, "")
+}
+
+/*
+NewMessageWithCode returns an error that prints just like fmt.Errorf with no
+line number, but including a code. The error code mechanism can be useful by
+itself even where stack traces with line numbers are not warranted.
+
+	ttl := req.URL.Query().Get("ttl")
+	if ttl == "" {
+		return 0, stacktrace.NewMessageWithCode(EcodeBadInput, "Missing ttl query parameter")
+	}
+*/
+func NewMessageWithCode(code ErrorCode, msg string, vals ...interface{}) error {
+	return &Stacktrace{
+		message:    fmt.Sprintf(msg, vals...),
+		code:       code,
+		statusCode: NoStatusCode,
+		kind:       KindUnknown,
+	}
+}
+
+func NewMessageWithStatusCode(code int, msg string, vals ...interface{}) error {
+	return &Stacktrace{
+		message:    fmt.Sprintf(msg, vals...),
+		code:       NoCode,
+		statusCode: code,
+		kind:       KindUnknown,
+	}
+}
+
+/*
+GetCode extracts the error code from a stacktrace error, including wrapped ones.
+
+	for i := 0; i < attempts; i++ {
+		err := Do()
+		if stacktrace.GetCode(err) != EcodeTimeout {
+			return err
+		}
+		// try a few more times
+	}
+	return stacktrace.NewError("timed out after %d attempts", attempts)
+
+GetCode returns the special value stacktrace.NoCode if err is nil or if there is
+no error code attached to err.
+*/
+func GetCode(err error) ErrorCode {
+	var trace *Stacktrace
+	if errors.As(err, &trace) {
+		return trace.code
+	}
+
+	return NoCode
+}
+
+/*
+GetStatusCode extracts the status code from a stacktrace error, including
+wrapped ones. GetStatusCode returns the special value stacktrace.NoStatusCode
+if err is nil or if there is no status code attached to err.
+*/
+func GetStatusCode(err error) int {
+	var trace *Stacktrace
+	if errors.As(err, &trace) {
+		return trace.statusCode
+	}
+
+	return NoStatusCode
+}
+
+/*
+MaxStackDepth is the maximum number of stack frames captured on each
+Stacktrace created by this package. The default of 32 is generous enough
+for most call chains; raise it if you expect deeper recursion, or lower
+it to bound the cost of very hot error paths.
+*/
+var MaxStackDepth = 32
+
+type Stacktrace struct {
+	message    string
+	cause      error
+	code       ErrorCode
+	statusCode int
+	kind       Kind
+	pcs        []uintptr
+	file       string
+	function   string
+	line       int
+}
+
+func create(cause error, code ErrorCode, statusCode int, kind Kind, msg string, vals ...interface{}) error {
+	// If no error code specified, inherit error code from the cause.
+	if code == NoCode {
+		code = GetCode(cause)
+	}
+
+	// If no status code specified, inherit status code from the cause.
+	if statusCode == NoStatusCode {
+		statusCode = GetStatusCode(cause)
+	}
+
+	// If no kind specified, inherit the kind from the cause.
+	if kind == KindUnknown {
+		kind = GetKind(cause)
+	}
+
+	err := &Stacktrace{
+		message:    fmt.Sprintf(msg, vals...),
+		cause:      cause,
+		code:       code,
+		statusCode: statusCode,
+		kind:       kind,
+	}
+
+	// Caller of create is NewError or Propagate, so user's code is 2 up.
+	// runtime.Callers counts skip differently than runtime.Caller: 0 is
+	// Callers itself, 1 is create, 2 is the NewError/Propagate wrapper, so
+	// 3 is the user's code.
+	pcs := make([]uintptr, MaxStackDepth)
+	n := runtime.Callers(3, pcs)
+	if n == 0 {
+		return err
+	}
+	err.pcs = pcs[:n]
+
+	// Resolving every frame with runtime.CallersFrames is only done lazily,
+	// on demand, by Frames(); here we eagerly resolve just the immediate
+	// caller so file/function/line keep working at the same cost as before.
+	frame, _ := runtime.CallersFrames(err.pcs[:1]).Next()
+	if frame.PC == 0 {
+		return err
+	}
+	file := frame.File
+	if CleanPath != nil {
+		file = CleanPath(file)
+	}
+	err.file, err.line = file, frame.Line
+	if frame.Function != "" {
+		err.function = shortFuncName(frame.Function)
+	}
+
+	return err
+}
+
+// Frames resolves and returns every stack frame captured when st was
+// created (up to MaxStackDepth of them), from the immediate caller
+// outward. Resolution happens lazily here, via runtime.CallersFrames,
+// rather than at creation time, so that creating an error only pays for
+// the cheap runtime.Callers walk.
+//
+// A Stacktrace with no captured pcs (for instance, one rebuilt from JSON by
+// UnmarshalJSON) falls back to its single file/function/line triple, so
+// that location information already present on the struct isn't dropped.
+func (st *Stacktrace) Frames() []runtime.Frame {
+	if len(st.pcs) == 0 {
+		if st.file == "" {
+			return nil
+		}
+		return []runtime.Frame{{File: st.file, Line: st.line, Function: st.function}}
+	}
+
+	frames := make([]runtime.Frame, 0, len(st.pcs))
+	callersFrames := runtime.CallersFrames(st.pcs)
+	for {
+		frame, more := callersFrames.Next()
+		if CleanPath != nil {
+			frame.File = CleanPath(frame.File)
+		}
+		frame.Function = shortFuncName(frame.Function)
+		frames = append(frames, frame)
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+/* "FuncName" or "Receiver.MethodName" */
+func ShortFuncName(f *runtime.Func) string {
+	return shortFuncName(f.Name())
+}
+
+func shortFuncName(longName string) string {
+	// longName is like one of these:
+	// - "github.com/iandri/etrace/package.FuncName"
+	// - "github.com/iandri/etrace/package.Receiver.MethodName"
+	// - "github.com/iandri/etrace/package.(*PtrReceiver).MethodName"
+	withoutPath := longName[strings.LastIndex(longName, "/")+1:]
+	withoutPackage := withoutPath[strings.Index(withoutPath, ".")+1:]
+
+	shortName := withoutPackage
+	shortName = strings.Replace(shortName, "(", "", 1)
+	shortName = strings.Replace(shortName, "*", "", 1)
+	shortName = strings.Replace(shortName, ")", "", 1)
+
+	return shortName
+}
+
+func (st *Stacktrace) Error() string {
+	return fmt.Sprint(st)
+}
+
+// ExitCode returns the exit code associated with the stacktrace error based on its error code. If the error code is
+// NoCode, return 1 (default); otherwise, returns the value of the error code.
+func (st *Stacktrace) ExitCode() int {
+	if st.code == NoCode {
+		return 1
+	}
+	return int(st.code)
+}
@@ -0,0 +1,74 @@
+package stacktrace
+
+import (
+	"strings"
+	"testing"
+)
+
+func deep3() error { return NewError("leaf failed") }
+func deep2() error { return Propagate(deep3(), "deep2 failed") }
+func deep1() error { return Propagate(deep2(), "deep1 failed") }
+
+func TestFramesCapturesMultipleLevels(t *testing.T) {
+	err, ok := deep1().(*Stacktrace)
+	if !ok {
+		t.Fatalf("deep1() is %T, want *Stacktrace", deep1())
+	}
+
+	frames := err.Frames()
+	if len(frames) < 2 {
+		t.Fatalf("expected at least 2 captured frames, got %d: %+v", len(frames), frames)
+	}
+	if !strings.Contains(frames[0].Function, "deep1") {
+		t.Errorf("frames[0].Function = %q, want it to mention deep1", frames[0].Function)
+	}
+	if !strings.Contains(frames[1].Function, "TestFramesCapturesMultipleLevels") {
+		t.Errorf("frames[1].Function = %q, want it to mention the test function", frames[1].Function)
+	}
+}
+
+func TestFramesFallsBackWithoutCapturedPCs(t *testing.T) {
+	st := &Stacktrace{
+		message:  "rebuilt from elsewhere",
+		file:     "some/file.go",
+		line:     42,
+		function: "someFunc",
+	}
+
+	frames := st.Frames()
+	if len(frames) != 1 {
+		t.Fatalf("expected exactly 1 fallback frame, got %d", len(frames))
+	}
+	if frames[0].File != "some/file.go" || frames[0].Line != 42 || frames[0].Function != "someFunc" {
+		t.Errorf("fallback frame = %+v, want file/line/function from the struct", frames[0])
+	}
+}
+
+func TestFramesNilWithNothingCaptured(t *testing.T) {
+	st := &Stacktrace{message: "no location info at all"}
+	if frames := st.Frames(); frames != nil {
+		t.Errorf("expected nil frames, got %+v", frames)
+	}
+}
+
+func TestFormatFullRendersEveryFrame(t *testing.T) {
+	err := deep1()
+	full := FormatFull(err.(*Stacktrace))
+
+	for _, want := range []string{"leaf failed", "deep2 failed", "deep1 failed", "TestFormatFullRendersEveryFrame"} {
+		if !strings.Contains(full, want) {
+			t.Errorf("FormatFull output missing %q:\n%s", want, full)
+		}
+	}
+}
+
+func TestMaxStackDepthCapsCapture(t *testing.T) {
+	original := MaxStackDepth
+	defer func() { MaxStackDepth = original }()
+
+	MaxStackDepth = 1
+	err := deep1().(*Stacktrace)
+	if n := len(err.Frames()); n != 1 {
+		t.Errorf("expected MaxStackDepth=1 to cap capture at 1 frame, got %d", n)
+	}
+}